@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mr-karan/doggo/pkg/resolvers"
+)
+
+// RunAXFR performs a zone transfer for zone against the first configured
+// nameserver, printing each envelope of records as it streams in off the
+// wire. It's invoked when QueryFlags.AXFR is set, e.g.
+// `doggo --axfr zone.example.`.
+func (hub *Hub) RunAXFR(zone string) error {
+	if len(hub.Nameservers) == 0 {
+		return fmt.Errorf("no nameserver configured for zone transfer of %s", zone)
+	}
+
+	var tsig *resolvers.TSIGConfig
+	if hub.QueryFlags.TSIGName != "" {
+		tsig = &resolvers.TSIGConfig{
+			KeyName:   hub.QueryFlags.TSIGName,
+			Algorithm: hub.QueryFlags.TSIGAlgo,
+			Secret:    hub.QueryFlags.TSIGSecret,
+		}
+	}
+
+	r, err := resolvers.NewAXFRResolver(hub.Nameservers[0].Address, tsig)
+	if err != nil {
+		return err
+	}
+
+	return r.Transfer(zone, hub.QueryFlags.IXFRSerial, tsig, hub.printResponse)
+}
+
+func (hub *Hub) runAXFR() error {
+	if len(hub.QueryFlags.QNames) == 0 {
+		return fmt.Errorf("--axfr needs a zone argument, e.g. doggo --axfr zone.example.")
+	}
+	return hub.RunAXFR(hub.QueryFlags.QNames[0])
+}
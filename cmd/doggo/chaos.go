@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mr-karan/doggo/pkg/resolvers"
+)
+
+// RunChaosQueries fires the CHAOS-class version.bind/hostname.bind/id.server
+// probes against every configured nameserver. It's invoked when
+// QueryFlags.Chaos is set, e.g. `doggo --chaos @ns1.example.net`. A
+// nameserver that errors is warned to stderr and skipped, matching
+// runQuery's per-nameserver error handling, so one unreachable nameserver
+// doesn't blank out every other server's results.
+func (hub *Hub) RunChaosQueries() ([]resolvers.Response, error) {
+	responses := make([]resolvers.Response, 0, len(hub.Nameservers))
+	for _, ns := range hub.Nameservers {
+		resp, err := resolvers.ChaosLookup(ns.Address)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "doggo:", err)
+			continue
+		}
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
+// runChaos fires the CHAOS-class queries and prints the results.
+func (hub *Hub) runChaos() error {
+	resps, err := hub.RunChaosQueries()
+	if err != nil {
+		return err
+	}
+	for _, resp := range resps {
+		hub.printResponse(resp)
+	}
+	return nil
+}
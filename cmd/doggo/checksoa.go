@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mr-karan/doggo/pkg/resolvers"
+)
+
+// RunCheckSOA implements the `check-soa` mode: it treats the first
+// configured resolver as the entry point to walk the zone's NS RRset, then
+// probes every authoritative nameserver directly for the zone's SOA record,
+// flagging any disagreement between them. It's invoked when QueryFlags.CheckSOA
+// is set, e.g. `doggo --check-soa example.com`.
+func (hub *Hub) RunCheckSOA(zone string) (resolvers.SOACheckResponse, error) {
+	if len(hub.Resolvers) == 0 {
+		return resolvers.SOACheckResponse{}, fmt.Errorf("no resolver configured to discover NS records for %s", zone)
+	}
+	return resolvers.CheckSOA(zone, hub.Resolvers[0])
+}
+
+// runCheckSOA resolves the zone to check from the first positional query
+// name and prints whether its nameservers agree.
+func (hub *Hub) runCheckSOA() error {
+	if len(hub.QueryFlags.QNames) == 0 {
+		return fmt.Errorf("--check-soa needs a zone argument, e.g. doggo --check-soa example.com")
+	}
+
+	resp, err := hub.RunCheckSOA(hub.QueryFlags.QNames[0])
+	if err != nil {
+		return err
+	}
+	printSOACheck(resp)
+	return nil
+}
+
+func printSOACheck(resp resolvers.SOACheckResponse) {
+	fmt.Printf("zone: %s  in-sync: %v\n", resp.Zone, resp.InSync)
+	for _, r := range resp.Results {
+		if r.Error != "" {
+			fmt.Printf("  %-32s error: %s\n", r.Nameserver, r.Error)
+			continue
+		}
+		fmt.Printf("  %-32s serial=%d refresh=%d retry=%d expire=%d minttl=%d rtt=%s\n",
+			r.Nameserver, r.Serial, r.Refresh, r.Retry, r.Expire, r.MinTTL, r.RTT)
+	}
+}
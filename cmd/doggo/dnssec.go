@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mr-karan/doggo/pkg/resolvers"
+)
+
+// runValidate walks and cryptographically validates the DNSSEC chain of
+// trust for every QName against the first configured nameserver. It's
+// invoked when QueryFlags.Validate is set, e.g. `doggo --validate example.com`.
+func (hub *Hub) runValidate() error {
+	if len(hub.QueryFlags.QNames) == 0 {
+		return fmt.Errorf("--validate needs a query name")
+	}
+	if len(hub.Nameservers) == 0 {
+		return fmt.Errorf("no nameserver configured")
+	}
+	server := hub.Nameservers[0].Address
+
+	for _, name := range hub.QueryFlags.QNames {
+		result, err := resolvers.ValidateChain(name, server)
+		if err != nil {
+			return err
+		}
+		printValidation(result)
+	}
+	return nil
+}
+
+func printValidation(result resolvers.ValidationResult) {
+	fmt.Printf("%s: %s\n", result.Name, result.Verdict)
+	for _, rr := range result.Records {
+		fmt.Printf("  %-32s %-8s %s\n", rr.Name, rr.Type, rr.Status)
+	}
+}
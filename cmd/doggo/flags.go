@@ -0,0 +1,67 @@
+package main
+
+// QueryFlags holds every CLI flag that configures a single invocation of
+// doggo.
+type QueryFlags struct {
+	// QNames are the positional arguments: the names (or, with Reverse,
+	// IPs) to query.
+	QNames []string
+	// QTypes are the RR types to query, e.g. "A", "AAAA", "MX". Defaults
+	// to ["A"] when empty.
+	QTypes []string
+	// Nameservers are the user-supplied `--nameserver` values, parsed by
+	// initNameserver. Falls back to the system resolver when empty.
+	Nameservers []string
+	// Ndots is how many labels a name needs before it's tried as
+	// absolute rather than qualified against the search list.
+	Ndots int
+	// UseSearchList enables qualifying bare names against the system
+	// search list.
+	UseSearchList bool
+
+	// CheckSOA switches to the `check-soa` mode: it discovers a zone's
+	// authoritative nameservers and checks whether their SOA serials
+	// agree, e.g. `doggo --check-soa example.com`.
+	CheckSOA bool
+
+	// Chaos fires the CHAOS-class diagnostic queries (version.bind,
+	// hostname.bind, id.server) against every configured nameserver
+	// instead of a regular lookup, e.g. `doggo --chaos @ns1.example.net`.
+	Chaos bool
+
+	// AXFR requests a full zone transfer instead of a regular lookup,
+	// e.g. `doggo --axfr zone.example.`. IXFRSerial, when non-zero,
+	// requests an incremental transfer since that serial instead.
+	AXFR       bool
+	IXFRSerial uint32
+	// TSIGName/TSIGAlgo/TSIGSecret authenticate the zone transfer,
+	// mirroring dig's `-y keyname:algo:secret` syntax. TSIGName empty
+	// means the transfer is unsigned.
+	TSIGName   string
+	TSIGAlgo   string
+	TSIGSecret string
+
+	// DNSSEC sets the EDNS0 DO bit on regular lookups so signed zones
+	// return their RRSIGs, and reports the reply's AD bit (the
+	// nameserver's own claim to have validated the answer) alongside the
+	// regular Response, e.g. `doggo --dnssec example.com`.
+	DNSSEC bool
+	// Validate switches to a separate mode that walks and cryptographically
+	// verifies the chain of trust for the queried name itself, instead of
+	// a regular lookup: it produces its own per-zone-cut ValidationResult
+	// rather than a Response, e.g. `doggo --validate example.com`.
+	Validate bool
+
+	// Unicode renders IDN names in responses as Unicode (U-label) instead
+	// of their on-the-wire punycode (A-label) form, e.g. `doggo --unicode café.example`.
+	Unicode bool
+
+	// Reverse switches to `-x`/`--reverse` mode: QNames are IP literals
+	// that get rewritten into PTR questions under in-addr.arpa/ip6.arpa
+	// instead of being looked up as-is, e.g. `doggo -x 192.0.2.1`.
+	Reverse bool
+	// ReverseMask requests an RFC 2317 classless reverse delegation name
+	// (/25 through /31) instead of the classful one, when non-zero, e.g.
+	// `doggo -x --reverse-mask 27 192.0.2.1`.
+	ReverseMask int
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/mr-karan/doggo/pkg/resolvers"
+)
+
+// Nameserver is a single resolver endpoint doggo was configured to query,
+// resolved from a `--nameserver` flag or the system default.
+type Nameserver struct {
+	Address string
+	Type    string
+}
+
+// ResolverOpts carries resolver-wide tunables threaded down from
+// QueryFlags (or inferred from system config) that every resolver
+// construction needs.
+type ResolverOpts struct {
+	Ndots      int
+	SearchList []string
+}
+
+// Hub ties together the parsed QueryFlags, the nameservers/resolvers built
+// from them, and drives the actual lookups.
+type Hub struct {
+	QueryFlags   QueryFlags
+	ResolverOpts ResolverOpts
+	Nameservers  []Nameserver
+	Resolvers    []resolvers.Resolver
+}
+
+// loadResolvers constructs a Resolver for every configured Nameserver that
+// fits the single-question Resolver shape. AXFR and mDNS nameservers are
+// driven through their own Run* methods instead, since zone transfers and
+// multicast fan-out don't fit that shape.
+func (hub *Hub) loadResolvers() error {
+	hub.Resolvers = nil
+	for _, ns := range hub.Nameservers {
+		if ns.Type == AXFRResolver || ns.Type == MDNSResolver {
+			continue
+		}
+		r, err := resolvers.NewUDPResolver(ns.Address)
+		if err != nil {
+			return fmt.Errorf("error constructing resolver for %s: %v", ns.Address, err)
+		}
+		hub.Resolvers = append(hub.Resolvers, r)
+	}
+	return nil
+}
+
+// Run dispatches to whichever query mode QueryFlags selected and prints
+// the result. The modes are mutually exclusive; check-soa takes priority
+// since it doesn't make sense combined with a regular lookup.
+func (hub *Hub) Run() error {
+	if err := hub.loadResolvers(); err != nil {
+		return err
+	}
+
+	switch {
+	case hub.QueryFlags.CheckSOA:
+		return hub.runCheckSOA()
+	case hub.QueryFlags.Chaos:
+		return hub.runChaos()
+	case hub.QueryFlags.AXFR:
+		return hub.runAXFR()
+	case hub.QueryFlags.Validate:
+		return hub.runValidate()
+	case hub.QueryFlags.Reverse:
+		return hub.runReverse()
+	default:
+		return hub.runQuery()
+	}
+}
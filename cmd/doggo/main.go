@@ -0,0 +1,74 @@
+// Command doggo is a command-line DNS client.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stringList is a repeatable, comma-splitting flag.Value, e.g.
+// `--type A --type AAAA,MX` yields ["A", "AAAA", "MX"].
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, strings.Split(v, ",")...)
+	return nil
+}
+
+func main() {
+	hub := &Hub{}
+
+	var qtypes stringList
+	flag.Var(&qtypes, "type", "RR type(s) to query, e.g. A, AAAA, MX (repeatable, comma separated)")
+	var nameservers stringList
+	flag.Var(&nameservers, "nameserver", "nameserver(s) to query, e.g. udp://1.1.1.1 (repeatable)")
+
+	flag.IntVar(&hub.QueryFlags.Ndots, "ndots", 1, "number of labels a name needs before it's tried as absolute")
+	flag.BoolVar(&hub.QueryFlags.UseSearchList, "search", true, "use the system search list to qualify names")
+
+	flag.BoolVar(&hub.QueryFlags.CheckSOA, "check-soa", false, "check SOA serial agreement across a zone's authoritative nameservers")
+	flag.BoolVar(&hub.QueryFlags.Chaos, "chaos", false, "fire CHAOS-class diagnostic queries (version.bind, hostname.bind, id.server) instead of a regular lookup")
+
+	flag.BoolVar(&hub.QueryFlags.AXFR, "axfr", false, "request a full zone transfer instead of a regular lookup")
+	flag.Func("ixfr", "request an incremental zone transfer since the given serial", func(v string) error {
+		serial, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return err
+		}
+		hub.QueryFlags.IXFRSerial = uint32(serial)
+		return nil
+	})
+	flag.StringVar(&hub.QueryFlags.TSIGName, "tsig-name", "", "TSIG key name for signing a zone transfer")
+	flag.StringVar(&hub.QueryFlags.TSIGAlgo, "tsig-algo", "hmac-sha256.", "TSIG algorithm")
+	flag.StringVar(&hub.QueryFlags.TSIGSecret, "tsig-secret", "", "base64-encoded TSIG secret")
+
+	flag.BoolVar(&hub.QueryFlags.DNSSEC, "dnssec", false, "request DNSSEC signatures (sets the EDNS0 DO bit)")
+	flag.BoolVar(&hub.QueryFlags.Validate, "validate", false, "cryptographically validate the DNSSEC chain of trust for the queried name")
+
+	flag.BoolVar(&hub.QueryFlags.Unicode, "unicode", false, "render IDN names in responses as Unicode instead of punycode")
+
+	flag.BoolVar(&hub.QueryFlags.Reverse, "reverse", false, "treat query names as IP addresses and look up their PTR records")
+	flag.BoolVar(&hub.QueryFlags.Reverse, "x", false, "shorthand for --reverse")
+	flag.IntVar(&hub.QueryFlags.ReverseMask, "reverse-mask", 0, "build an RFC 2317 classless reverse delegation name with this mask (/25-/31) instead of the classful one")
+
+	flag.Parse()
+
+	hub.QueryFlags.QTypes = qtypes
+	hub.QueryFlags.Nameservers = nameservers
+	hub.QueryFlags.QNames = flag.Args()
+
+	if err := hub.loadNameservers(); err != nil {
+		fmt.Fprintln(os.Stderr, "doggo:", err)
+		os.Exit(1)
+	}
+
+	if err := hub.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, "doggo:", err)
+		os.Exit(1)
+	}
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"strings"
 
+	"github.com/miekg/dns"
 	"github.com/mr-karan/doggo/pkg/config"
+	"github.com/mr-karan/doggo/pkg/resolvers"
 )
 
 const (
@@ -19,6 +22,15 @@ const (
 	DOHResolver    = "doh"
 	TCPResolver    = "tcp"
 	DOTResolver    = "dot"
+	AXFRResolver   = "axfr"
+	MDNSResolver   = "mdns"
+	// MDNSDomain is the suffix that triggers automatic multicast DNS
+	// resolution for a query name, per RFC 6762.
+	MDNSDomain = ".local."
+	// MDNSGroupAddrs documents where mDNS queries for this nameserver
+	// actually go out to, since a single dial address doesn't make sense
+	// for a mode that always fans out to both multicast groups.
+	MDNSGroupAddrs = "224.0.0.251:5353,[ff02::fb]:5353"
 )
 
 // loadNameservers reads all the user given
@@ -108,5 +120,47 @@ func initNameserver(n string) (Nameserver, error) {
 			ns.Address = net.JoinHostPort(u.Hostname(), u.Port())
 		}
 	}
+	if u.Scheme == "axfr" {
+		ns.Type = AXFRResolver
+		if u.Port() == "" {
+			ns.Address = net.JoinHostPort(u.Hostname(), DefaultTCPPort)
+		} else {
+			ns.Address = net.JoinHostPort(u.Hostname(), u.Port())
+		}
+	}
+	if u.Scheme == "mdns" {
+		ns.Type = MDNSResolver
+		ns.Address = MDNSGroupAddrs
+	}
 	return ns, nil
 }
+
+// isMDNSQuery reports whether name should be resolved over multicast DNS,
+// i.e. it falls under the `.local.` domain reserved by RFC 6762.
+func isMDNSQuery(name string) bool {
+	return strings.HasSuffix(dns.Fqdn(name), MDNSDomain)
+}
+
+// buildReverseQuestions rewrites every IP literal in addrs into a PTR
+// question under in-addr.arpa/ip6.arpa, for the `-x`/`--reverse` flag. When
+// reverseMask is non-zero, it builds an RFC 2317 classless delegation name
+// instead, so operators can probe a delegated reverse sub-zone.
+func buildReverseQuestions(addrs []string, reverseMask int) ([]dns.Question, error) {
+	questions := make([]dns.Question, 0, len(addrs))
+	for _, addr := range addrs {
+		var (
+			q   dns.Question
+			err error
+		)
+		if reverseMask > 0 {
+			q, err = resolvers.BuildClasslessReverseQuestion(addr, reverseMask)
+		} else {
+			q, err = resolvers.BuildReverseQuestion(addr)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error building reverse query for %s: %v", addr, err)
+		}
+		questions = append(questions, q)
+	}
+	return questions, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/miekg/dns"
+	"github.com/mr-karan/doggo/pkg/resolvers"
+)
+
+// runQuery is the default query mode: it resolves every configured QName
+// against every configured nameserver and prints the combined answers.
+func (hub *Hub) runQuery() error {
+	if len(hub.QueryFlags.QNames) == 0 {
+		return fmt.Errorf("no query name given")
+	}
+
+	qtypes := hub.QueryFlags.QTypes
+	if len(qtypes) == 0 {
+		qtypes = []string{"A"}
+	}
+
+	for _, name := range hub.QueryFlags.QNames {
+		for _, qtype := range qtypes {
+			t, ok := dns.StringToType[qtype]
+			if !ok {
+				return fmt.Errorf("unknown query type %q", qtype)
+			}
+			question := dns.Question{Name: dns.Fqdn(name), Qtype: t, Qclass: dns.ClassINET}
+
+			if isMDNSQuery(name) {
+				resp, err := hub.lookupMDNS(question)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "doggo:", err)
+					continue
+				}
+				hub.printResponse(resp)
+				continue
+			}
+
+			for _, ns := range hub.Nameservers {
+				resp, err := hub.lookup(ns, question)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "doggo:", err)
+					continue
+				}
+				hub.printResponse(resp)
+			}
+		}
+	}
+	return nil
+}
+
+// lookup sends question to ns, requesting DNSSEC signatures when
+// QueryFlags.DNSSEC is set. A nameserver explicitly configured with the
+// `mdns://` scheme is queried over multicast DNS regardless of name.
+func (hub *Hub) lookup(ns Nameserver, question dns.Question) (resolvers.Response, error) {
+	if ns.Type == MDNSResolver {
+		return hub.lookupMDNS(question)
+	}
+	if hub.QueryFlags.DNSSEC {
+		return resolvers.DNSSECLookup(question, ns.Address, hub.ResolverOpts.Ndots, hub.ResolverOpts.SearchList)
+	}
+	r, err := resolvers.NewUDPResolver(ns.Address)
+	if err != nil {
+		return resolvers.Response{}, err
+	}
+	return r.Lookup(question)
+}
+
+// lookupMDNS sends question over multicast DNS, per RFC 6762, for names
+// under the `.local.` domain.
+func (hub *Hub) lookupMDNS(question dns.Question) (resolvers.Response, error) {
+	r, err := resolvers.NewMDNSResolver(0)
+	if err != nil {
+		return resolvers.Response{}, err
+	}
+	return r.Lookup(question)
+}
+
+// printResponse renders resp's answers, one record per line. Under
+// --dnssec it also reports whether the replying nameserver set the AD bit,
+// i.e. claims to have validated the answer itself; this is the
+// nameserver's word, not an independent check (--validate does that).
+func (hub *Hub) printResponse(resp resolvers.Response) {
+	if hub.QueryFlags.Unicode {
+		resp = resolvers.ToUnicodeDisplay(resp)
+	}
+	if hub.QueryFlags.DNSSEC {
+		fmt.Printf("ad: %v\n", resp.AD)
+	}
+	for _, a := range resp.Answers {
+		fmt.Printf("%-32s %-6s %-6s %-6s %-20s %s\n", a.Name, a.Type, a.Class, a.TTL, a.Address, a.Nameserver)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runReverse implements the `-x`/`--reverse` mode: it rewrites every
+// positional QName (an IP literal) into a PTR question under
+// in-addr.arpa/ip6.arpa, or an RFC 2317 classless delegation name when
+// ReverseMask is set, then queries it against every configured nameserver.
+func (hub *Hub) runReverse() error {
+	if len(hub.QueryFlags.QNames) == 0 {
+		return fmt.Errorf("--reverse needs at least one IP address, e.g. doggo -x 192.0.2.1")
+	}
+
+	questions, err := buildReverseQuestions(hub.QueryFlags.QNames, hub.QueryFlags.ReverseMask)
+	if err != nil {
+		return err
+	}
+
+	for _, question := range questions {
+		for _, ns := range hub.Nameservers {
+			resp, err := hub.lookup(ns, question)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "doggo:", err)
+				continue
+			}
+			hub.printResponse(resp)
+		}
+	}
+	return nil
+}
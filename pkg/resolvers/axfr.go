@@ -0,0 +1,82 @@
+package resolvers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// TSIGConfig holds the key name, algorithm and secret used to authenticate a
+// zone transfer, mirroring dig's `-y keyname:algo:secret` syntax.
+type TSIGConfig struct {
+	KeyName   string
+	Algorithm string
+	Secret    string
+}
+
+// AXFRResolver performs full (AXFR) or incremental (IXFR) zone transfers
+// against a single authoritative nameserver. Unlike the other resolvers in
+// this package it does not use the single-shot `dns.Client.Exchange`, but
+// `dns.Transfer`'s streaming API, since a zone can be arbitrarily large.
+type AXFRResolver struct {
+	server   string
+	transfer *dns.Transfer
+}
+
+// NewAXFRResolver instantiates an AXFRResolver for the given nameserver
+// address. If tsig is non-nil, the transfer is signed with the supplied key.
+func NewAXFRResolver(server string, tsig *TSIGConfig) (*AXFRResolver, error) {
+	r := &AXFRResolver{
+		server:   server,
+		transfer: &dns.Transfer{},
+	}
+	if tsig != nil {
+		r.transfer.TsigSecret = map[string]string{dns.Fqdn(tsig.KeyName): tsig.Secret}
+	}
+	return r, nil
+}
+
+// Transfer streams an AXFR for zone, or an IXFR when serial is non-zero,
+// invoking onEnvelope once per envelope of records as it arrives from the
+// wire so that large zones don't need to be buffered in memory.
+func (r *AXFRResolver) Transfer(zone string, serial uint32, tsig *TSIGConfig, onEnvelope func(Response)) error {
+	zone = dns.Fqdn(zone)
+
+	msg := new(dns.Msg)
+	if serial > 0 {
+		msg.SetIxfr(zone, serial, "", "")
+	} else {
+		msg.SetAxfr(zone)
+	}
+	if tsig != nil {
+		msg.SetTsig(dns.Fqdn(tsig.KeyName), tsig.Algorithm, 300, time.Now().Unix())
+	}
+
+	envelopeChan, err := r.transfer.In(msg, r.server)
+	if err != nil {
+		return fmt.Errorf("error starting zone transfer for %s: %v", zone, err)
+	}
+
+	for envelope := range envelopeChan {
+		if envelope.Error != nil {
+			return fmt.Errorf("error during zone transfer for %s: %v", zone, envelope.Error)
+		}
+
+		var resp Response
+		for _, rr := range envelope.RR {
+			h := rr.Header()
+			resp.Answers = append(resp.Answers, Answer{
+				Name:       h.Name,
+				Type:       dns.Type(h.Rrtype).String(),
+				TTL:        fmt.Sprintf("%ds", h.Ttl),
+				Class:      dns.Class(h.Class).String(),
+				Address:    rdataString(rr),
+				Nameserver: r.server,
+			})
+		}
+		onEnvelope(resp)
+	}
+
+	return nil
+}
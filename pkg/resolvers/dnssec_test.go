@@ -0,0 +1,339 @@
+package resolvers
+
+import (
+	"crypto"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestOverallVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []RRValidation
+		want    ChainVerdict
+	}{
+		{"empty", nil, Secure},
+		{"all secure", []RRValidation{{Status: Secure}, {Status: Secure}}, Secure},
+		{"one insecure", []RRValidation{{Status: Secure}, {Status: Insecure}}, Insecure},
+		{"one bogus wins", []RRValidation{{Status: Insecure}, {Status: Bogus}, {Status: Secure}}, Bogus},
+		{"indeterminate without bogus", []RRValidation{{Status: Secure}, {Status: Indeterminate}}, Indeterminate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overallVerdict(tt.records); got != tt.want {
+				t.Errorf("overallVerdict(%v) = %v, want %v", tt.records, got, tt.want)
+			}
+		})
+	}
+}
+
+// signedZone is a synthetic, self-signed zone cut: a DNSKEY, the RRSIG
+// covering it, and (for non-root zones) the DS published about it by its
+// parent, plus the RRSIG covering *that* DS signed with the parent's key.
+type signedZone struct {
+	key  *dns.DNSKEY
+	priv crypto.Signer
+}
+
+func newSignedZone(t *testing.T, zone string) signedZone {
+	t.Helper()
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: zone, Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.RSASHA256,
+	}
+	priv, err := key.Generate(1024)
+	if err != nil {
+		t.Fatalf("generate key for %s: %v", zone, err)
+	}
+	signer, ok := priv.(crypto.Signer)
+	if !ok {
+		t.Fatalf("generated key for %s is not a crypto.Signer", zone)
+	}
+	return signedZone{key: key, priv: signer}
+}
+
+func sign(t *testing.T, zone string, typeCovered uint16, signer crypto.Signer, signerZone string, keyTag uint16, rrset []dns.RR) *dns.RRSIG {
+	t.Helper()
+	sig := &dns.RRSIG{
+		Hdr:         dns.RR_Header{Name: zone, Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 3600},
+		TypeCovered: typeCovered,
+		Algorithm:   dns.RSASHA256,
+		Labels:      uint8(dns.CountLabel(zone)),
+		OrigTtl:     3600,
+		Expiration:  uint32(time.Now().Add(24 * time.Hour).Unix()),
+		Inception:   uint32(time.Now().Add(-1 * time.Hour).Unix()),
+		SignerName:  signerZone,
+		KeyTag:      keyTag,
+	}
+	if err := sig.Sign(signer, rrset); err != nil {
+		t.Fatalf("sign %s: %v", zone, err)
+	}
+	return sig
+}
+
+// newTestRoot builds a synthetic, self-signed root zone and points
+// RootTrustAnchor at its DS digest for the duration of the test, so
+// ValidateChain's root-pinning step can be exercised against a fake
+// authority instead of the real IANA anchor.
+func newTestRoot(t *testing.T) (signedZone, *dns.RRSIG) {
+	t.Helper()
+	root := newSignedZone(t, rootZone)
+	sig := sign(t, rootZone, dns.TypeDNSKEY, root.priv, rootZone, root.key.KeyTag(), []dns.RR{root.key})
+
+	rootDS := root.key.ToDS(dns.SHA256)
+	rootDS.Hdr = dns.RR_Header{Name: rootZone, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+
+	orig := RootTrustAnchor
+	RootTrustAnchor = []*dns.DS{rootDS}
+	t.Cleanup(func() { RootTrustAnchor = orig })
+
+	return root, sig
+}
+
+// startFakeAuthority serves canned DNSKEY/DS answers over UDP, plus a
+// synthetic SOA for every name that has a DNSKEY entry (so findZoneApex can
+// discover the zone cuts the same way it would against a real authority),
+// so ValidateChain can be exercised end-to-end without a real resolver.
+func startFakeAuthority(t *testing.T, dnskeys, ds map[string][]dns.RR) string {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, dns.DefaultMsgSize)
+		for {
+			n, addr, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			req := new(dns.Msg)
+			if err := req.Unpack(buf[:n]); err != nil {
+				continue
+			}
+			resp := new(dns.Msg)
+			resp.SetReply(req)
+			q := req.Question[0]
+			switch q.Qtype {
+			case dns.TypeDNSKEY:
+				resp.Answer = dnskeys[q.Name]
+			case dns.TypeDS:
+				resp.Answer = ds[q.Name]
+			case dns.TypeSOA:
+				if _, ok := dnskeys[q.Name]; ok {
+					resp.Answer = []dns.RR{&dns.SOA{
+						Hdr:     dns.RR_Header{Name: q.Name, Rrtype: dns.TypeSOA, Class: dns.ClassINET, Ttl: 3600},
+						Ns:      "ns1." + q.Name,
+						Mbox:    "hostmaster." + q.Name,
+						Serial:  1,
+						Refresh: 3600,
+						Retry:   900,
+						Expire:  604800,
+						Minttl:  3600,
+					}}
+				}
+			}
+			packed, err := resp.Pack()
+			if err != nil {
+				continue
+			}
+			_, _ = conn.WriteToUDP(packed, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// TestValidateChainTwoLevel exercises ValidateChain end-to-end against a
+// synthetic "example.com." -> "com." chain with real (if tiny) RSA keys and
+// signatures, guarding against the DS/DNSKEY zone-cut mismatch: the DS
+// published about example.com must be checked against example.com's own
+// DNSKEY, not com.'s.
+func TestValidateChainTwoLevel(t *testing.T) {
+	const child = "example.com."
+	const parentZone = "com."
+
+	childZone := newSignedZone(t, child)
+	parentZ := newSignedZone(t, parentZone)
+	root, rootSig := newTestRoot(t)
+
+	childSig := sign(t, child, dns.TypeDNSKEY, childZone.priv, child, childZone.key.KeyTag(), []dns.RR{childZone.key})
+	parentSig := sign(t, parentZone, dns.TypeDNSKEY, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{parentZ.key})
+
+	childDS := childZone.key.ToDS(dns.SHA256)
+	childDS.Hdr = dns.RR_Header{Name: child, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	childDSSig := sign(t, child, dns.TypeDS, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{childDS})
+
+	// com.'s DS is published and signed by the root, not by com. itself.
+	parentDS := parentZ.key.ToDS(dns.SHA256)
+	parentDS.Hdr = dns.RR_Header{Name: parentZone, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	parentDSSig := sign(t, parentZone, dns.TypeDS, root.priv, rootZone, root.key.KeyTag(), []dns.RR{parentDS})
+
+	server := startFakeAuthority(t,
+		map[string][]dns.RR{
+			child:      {childZone.key, childSig},
+			parentZone: {parentZ.key, parentSig},
+			rootZone:   {root.key, rootSig},
+		},
+		map[string][]dns.RR{
+			child:      {childDS, childDSSig},
+			parentZone: {parentDS, parentDSSig},
+		},
+	)
+
+	result, err := ValidateChain(child, server)
+	if err != nil {
+		t.Fatalf("ValidateChain: %v", err)
+	}
+	if result.Verdict != Secure {
+		t.Fatalf("verdict = %v, want Secure; records=%+v", result.Verdict, result.Records)
+	}
+}
+
+// TestValidateChainNonApexName confirms that validating a name that isn't
+// itself a zone apex (e.g. "www.example.com.", the common case) still
+// returns Secure against a correctly signed chain, instead of querying
+// DNSKEY for the non-apex name, finding nothing, and folding that into an
+// unrecoverable Insecure verdict.
+func TestValidateChainNonApexName(t *testing.T) {
+	const name = "www.example.com."
+	const child = "example.com."
+	const parentZone = "com."
+
+	childZone := newSignedZone(t, child)
+	parentZ := newSignedZone(t, parentZone)
+	root, rootSig := newTestRoot(t)
+
+	childSig := sign(t, child, dns.TypeDNSKEY, childZone.priv, child, childZone.key.KeyTag(), []dns.RR{childZone.key})
+	parentSig := sign(t, parentZone, dns.TypeDNSKEY, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{parentZ.key})
+
+	childDS := childZone.key.ToDS(dns.SHA256)
+	childDS.Hdr = dns.RR_Header{Name: child, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	childDSSig := sign(t, child, dns.TypeDS, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{childDS})
+
+	parentDS := parentZ.key.ToDS(dns.SHA256)
+	parentDS.Hdr = dns.RR_Header{Name: parentZone, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	parentDSSig := sign(t, parentZone, dns.TypeDS, root.priv, rootZone, root.key.KeyTag(), []dns.RR{parentDS})
+
+	server := startFakeAuthority(t,
+		map[string][]dns.RR{
+			child:      {childZone.key, childSig},
+			parentZone: {parentZ.key, parentSig},
+			rootZone:   {root.key, rootSig},
+		},
+		map[string][]dns.RR{
+			child:      {childDS, childDSSig},
+			parentZone: {parentDS, parentDSSig},
+		},
+	)
+
+	result, err := ValidateChain(name, server)
+	if err != nil {
+		t.Fatalf("ValidateChain: %v", err)
+	}
+	if result.Verdict != Secure {
+		t.Fatalf("verdict = %v, want Secure; records=%+v", result.Verdict, result.Records)
+	}
+	if len(result.Records) != 3 {
+		t.Fatalf("len(records) = %d, want 3 (example.com., com., .); records=%+v", len(result.Records), result.Records)
+	}
+}
+
+// TestValidateChainTwoLevelMismatchedDS confirms a DS that doesn't match
+// the child's actual DNSKEY is still caught as Bogus once the comparison
+// happens against the right zone cut.
+func TestValidateChainTwoLevelMismatchedDS(t *testing.T) {
+	const child = "example.com."
+	const parentZone = "com."
+
+	childZone := newSignedZone(t, child)
+	otherZone := newSignedZone(t, child) // unrelated key, to produce a DS that won't match childZone's key
+	parentZ := newSignedZone(t, parentZone)
+	root, rootSig := newTestRoot(t)
+
+	childSig := sign(t, child, dns.TypeDNSKEY, childZone.priv, child, childZone.key.KeyTag(), []dns.RR{childZone.key})
+	parentSig := sign(t, parentZone, dns.TypeDNSKEY, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{parentZ.key})
+
+	wrongDS := otherZone.key.ToDS(dns.SHA256)
+	wrongDS.Hdr = dns.RR_Header{Name: child, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	wrongDSSig := sign(t, child, dns.TypeDS, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{wrongDS})
+
+	// com.'s DS is published and signed by the root, not by com. itself.
+	parentDS := parentZ.key.ToDS(dns.SHA256)
+	parentDS.Hdr = dns.RR_Header{Name: parentZone, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	parentDSSig := sign(t, parentZone, dns.TypeDS, root.priv, rootZone, root.key.KeyTag(), []dns.RR{parentDS})
+
+	server := startFakeAuthority(t,
+		map[string][]dns.RR{
+			child:      {childZone.key, childSig},
+			parentZone: {parentZ.key, parentSig},
+			rootZone:   {root.key, rootSig},
+		},
+		map[string][]dns.RR{
+			child:      {wrongDS, wrongDSSig},
+			parentZone: {parentDS, parentDSSig},
+		},
+	)
+
+	result, err := ValidateChain(child, server)
+	if err != nil {
+		t.Fatalf("ValidateChain: %v", err)
+	}
+	if result.Verdict != Bogus {
+		t.Fatalf("verdict = %v, want Bogus; records=%+v", result.Verdict, result.Records)
+	}
+}
+
+// TestValidateChainForgedRoot confirms a chain that's internally
+// consistent end-to-end, but whose "root" isn't the one pinned in
+// RootTrustAnchor, is still caught as Bogus rather than Secure. This is
+// the attack ValidateChain needs to catch: someone who controls every
+// zone cut in a fabricated chain, including its own fake root.
+func TestValidateChainForgedRoot(t *testing.T) {
+	const child = "example.com."
+	const parentZone = "com."
+
+	childZone := newSignedZone(t, child)
+	parentZ := newSignedZone(t, parentZone)
+	forgedRoot := newSignedZone(t, rootZone) // self-consistent, but never pinned as the anchor
+
+	childSig := sign(t, child, dns.TypeDNSKEY, childZone.priv, child, childZone.key.KeyTag(), []dns.RR{childZone.key})
+	parentSig := sign(t, parentZone, dns.TypeDNSKEY, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{parentZ.key})
+	forgedRootSig := sign(t, rootZone, dns.TypeDNSKEY, forgedRoot.priv, rootZone, forgedRoot.key.KeyTag(), []dns.RR{forgedRoot.key})
+
+	childDS := childZone.key.ToDS(dns.SHA256)
+	childDS.Hdr = dns.RR_Header{Name: child, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	childDSSig := sign(t, child, dns.TypeDS, parentZ.priv, parentZone, parentZ.key.KeyTag(), []dns.RR{childDS})
+
+	parentDS := parentZ.key.ToDS(dns.SHA256)
+	parentDS.Hdr = dns.RR_Header{Name: parentZone, Rrtype: dns.TypeDS, Class: dns.ClassINET, Ttl: 3600}
+	parentDSSig := sign(t, parentZone, dns.TypeDS, forgedRoot.priv, rootZone, forgedRoot.key.KeyTag(), []dns.RR{parentDS})
+
+	server := startFakeAuthority(t,
+		map[string][]dns.RR{
+			child:      {childZone.key, childSig},
+			parentZone: {parentZ.key, parentSig},
+			rootZone:   {forgedRoot.key, forgedRootSig},
+		},
+		map[string][]dns.RR{
+			child:      {childDS, childDSSig},
+			parentZone: {parentDS, parentDSSig},
+		},
+	)
+
+	result, err := ValidateChain(child, server)
+	if err != nil {
+		t.Fatalf("ValidateChain: %v", err)
+	}
+	if result.Verdict != Bogus {
+		t.Fatalf("verdict = %v, want Bogus; records=%+v", result.Verdict, result.Records)
+	}
+}
@@ -0,0 +1,47 @@
+package resolvers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+	"golang.org/x/net/idna"
+)
+
+// isReverseName reports whether name is a PTR/reverse-lookup name
+// (in-addr.arpa or ip6.arpa), which must never be put through IDNA
+// processing.
+func isReverseName(name string) bool {
+	name = strings.ToLower(dns.Fqdn(name))
+	return strings.HasSuffix(name, "in-addr.arpa.") || strings.HasSuffix(name, "ip6.arpa.")
+}
+
+// toASCII normalizes a user-typed query name through IDNA2008, converting a
+// Unicode label like "café.example" to its ASCII-compatible A-label
+// ("xn--caf-dma.example"). Already-encoded `xn--` labels and plain ASCII
+// names pass through unchanged. Reverse-lookup names are never processed.
+func toASCII(name string) (string, error) {
+	if isReverseName(name) {
+		return name, nil
+	}
+	ascii, err := idna.Lookup.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("invalid unicode domain name %q: %v", name, err)
+	}
+	return ascii, nil
+}
+
+// toUnicode converts an A-label query name back to its Unicode U-label for
+// display, e.g. "xn--caf-dma.example." becomes "café.example.". Used when
+// the user passes `--unicode`. Reverse-lookup names and names that fail to
+// decode are returned unchanged.
+func toUnicode(name string) string {
+	if isReverseName(name) {
+		return name
+	}
+	unicode, err := idna.Lookup.ToUnicode(name)
+	if err != nil {
+		return name
+	}
+	return unicode
+}
@@ -0,0 +1,247 @@
+package resolvers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	mdnsIPv4Addr = "224.0.0.251:5353"
+	mdnsIPv6Addr = "[ff02::fb]:5353"
+	// mdnsDefaultWindow is how long the resolver keeps listening for
+	// responses after sending a multicast query, since (unlike a unicast
+	// lookup) multiple responders may legitimately answer.
+	mdnsDefaultWindow = 1 * time.Second
+)
+
+// MDNSResolver resolves names ending in `.local.` (or addressed via the
+// `mdns://` scheme) by multicasting the query per RFC 6762 and collecting
+// every reply received within a fixed window, rather than returning on the
+// first response.
+type MDNSResolver struct {
+	window time.Duration
+}
+
+// ServiceInstance groups the SRV/TXT/A/AAAA records describing a single
+// PTR-discovered service instance (e.g. "My Printer._http._tcp.local."),
+// correlated from the Additional section of the mDNS reply that announced
+// it, per RFC 6763's DNS-SD conventions.
+type ServiceInstance struct {
+	Instance string
+	SRV      *Answer
+	TXT      []Answer
+	Addrs    []Answer
+}
+
+// NewMDNSResolver instantiates a MDNSResolver. A zero window falls back to
+// mdnsDefaultWindow.
+func NewMDNSResolver(window time.Duration) (*MDNSResolver, error) {
+	if window <= 0 {
+		window = mdnsDefaultWindow
+	}
+	return &MDNSResolver{window: window}, nil
+}
+
+// Lookup multicasts question on both the IPv4 and IPv6 mDNS groups and
+// collects answers from every responder that replies within the resolver's
+// window. PTR answers (used for `_service._proto.local.` discovery) are
+// correlated with their SRV/TXT/A/AAAA records from the Additional section.
+func (r *MDNSResolver) Lookup(question dns.Question) (Response, error) {
+	msg := dns.Msg{}
+	msg.Id = dns.Id()
+	msg.RecursionDesired = false
+	msg.Question = []dns.Question{question}
+
+	var resp Response
+
+	for _, group := range []string{mdnsIPv4Addr, mdnsIPv6Addr} {
+		replies, err := r.queryGroup(&msg, group)
+		if err != nil {
+			// The host may not have an interface for this address family;
+			// that's not fatal, just skip this group.
+			continue
+		}
+
+		for _, reply := range replies {
+			parsed := parseMessage(reply.msg, reply.rtt, group)
+			resp.Answers = append(resp.Answers, parsed.Answers...)
+			resp.Authorities = append(resp.Authorities, parsed.Authorities...)
+			resp.Answers = append(resp.Answers, extraAnswers(reply.msg, reply.rtt, group)...)
+			resp.Services = append(resp.Services, correlateServices(reply.msg, reply.rtt, group)...)
+		}
+	}
+
+	return resp, nil
+}
+
+// mdnsReply pairs a decoded reply with how long it took to arrive after the
+// query was sent.
+type mdnsReply struct {
+	msg *dns.Msg
+	rtt time.Duration
+}
+
+// queryGroup joins the mDNS multicast group and sends msg to it, then
+// collects every reply received within the resolver's window. It uses an
+// unconnected, group-joined socket (net.ListenMulticastUDP) rather than a
+// connected net.Dial socket: responders answer from their own unicast
+// address, not from the multicast group address itself, so a connected
+// socket (which only accepts datagrams from its dialed peer) would filter
+// out every genuine reply.
+func (r *MDNSResolver) queryGroup(msg *dns.Msg, group string) ([]mdnsReply, error) {
+	groupAddr, err := net.ResolveUDPAddr("udp", group)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving mdns group address %s: %v", group, err)
+	}
+
+	conn, err := net.ListenMulticastUDP("udp", nil, groupAddr)
+	if err != nil {
+		return nil, fmt.Errorf("error joining mdns group %s: %v", group, err)
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("error packing mdns query: %v", err)
+	}
+	if _, err := conn.WriteToUDP(packed, groupAddr); err != nil {
+		return nil, fmt.Errorf("error sending mdns query to %s: %v", group, err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(r.window))
+	var replies []mdnsReply
+	buf := make([]byte, dns.DefaultMsgSize)
+	for {
+		n, _, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// Read deadline exceeded: stop waiting on this group.
+			break
+		}
+		reply := new(dns.Msg)
+		if err := reply.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		replies = append(replies, mdnsReply{msg: reply, rtt: time.Since(start)})
+	}
+	return replies, nil
+}
+
+// extraAnswers parses the Additional section of a mDNS reply (SRV, TXT,
+// A/AAAA glue that accompanies a PTR service-discovery answer) into Answer
+// records, the same way parseMessage handles the Answer section.
+func extraAnswers(msg *dns.Msg, rtt time.Duration, server string) []Answer {
+	var answers []Answer
+	timeTaken := fmt.Sprintf("%dms", rtt.Milliseconds())
+
+	for _, rr := range msg.Extra {
+		h := rr.Header()
+		addr := ""
+		switch t := rr.(type) {
+		case *dns.A:
+			addr = t.A.String()
+		case *dns.AAAA:
+			addr = t.AAAA.String()
+		case *dns.SRV:
+			addr = fmt.Sprintf("%d %d %s:%d", t.Priority, t.Weight, t.Target, t.Port)
+		case *dns.TXT:
+			addr = strings.Join(t.Txt, " ")
+		default:
+			continue
+		}
+		answers = append(answers, Answer{
+			Name:       h.Name,
+			Type:       dns.Type(h.Rrtype).String(),
+			TTL:        fmt.Sprintf("%ds", h.Ttl),
+			Class:      dns.Class(h.Class).String(),
+			Address:    addr,
+			RTT:        timeTaken,
+			Nameserver: server,
+		})
+	}
+	return answers
+}
+
+// correlateServices follows each PTR answer in msg.Answer (as produced by a
+// `_service._proto.local.` discovery query) to the SRV/TXT record(s) in
+// msg.Extra named after it, and in turn to the A/AAAA glue named after the
+// SRV's target, building the structured Response.Services that RFC 6763
+// service discovery needs instead of a flat, uncorrelated record list.
+func correlateServices(msg *dns.Msg, rtt time.Duration, server string) []ServiceInstance {
+	var services []ServiceInstance
+	timeTaken := fmt.Sprintf("%dms", rtt.Milliseconds())
+
+	for _, rr := range msg.Answer {
+		ptr, ok := rr.(*dns.PTR)
+		if !ok {
+			continue
+		}
+		instance := ServiceInstance{Instance: ptr.Ptr}
+
+		var srvTarget string
+		for _, extra := range msg.Extra {
+			h := extra.Header()
+			if h.Name != ptr.Ptr {
+				continue
+			}
+			switch t := extra.(type) {
+			case *dns.SRV:
+				srvTarget = t.Target
+				srv := Answer{
+					Name:       h.Name,
+					Type:       "SRV",
+					TTL:        fmt.Sprintf("%ds", h.Ttl),
+					Class:      dns.Class(h.Class).String(),
+					Address:    fmt.Sprintf("%d %d %s:%d", t.Priority, t.Weight, t.Target, t.Port),
+					RTT:        timeTaken,
+					Nameserver: server,
+				}
+				instance.SRV = &srv
+			case *dns.TXT:
+				instance.TXT = append(instance.TXT, Answer{
+					Name:       h.Name,
+					Type:       "TXT",
+					TTL:        fmt.Sprintf("%ds", h.Ttl),
+					Class:      dns.Class(h.Class).String(),
+					Address:    strings.Join(t.Txt, " "),
+					RTT:        timeTaken,
+					Nameserver: server,
+				})
+			}
+		}
+
+		if srvTarget != "" {
+			for _, extra := range msg.Extra {
+				h := extra.Header()
+				if h.Name != srvTarget {
+					continue
+				}
+				addr := ""
+				switch t := extra.(type) {
+				case *dns.A:
+					addr = t.A.String()
+				case *dns.AAAA:
+					addr = t.AAAA.String()
+				default:
+					continue
+				}
+				instance.Addrs = append(instance.Addrs, Answer{
+					Name:       h.Name,
+					Type:       dns.Type(h.Rrtype).String(),
+					TTL:        fmt.Sprintf("%ds", h.Ttl),
+					Class:      dns.Class(h.Class).String(),
+					Address:    addr,
+					RTT:        timeTaken,
+					Nameserver: server,
+				})
+			}
+		}
+
+		services = append(services, instance)
+	}
+	return services
+}
@@ -0,0 +1,88 @@
+package resolvers
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestExtraAnswers(t *testing.T) {
+	msg := &dns.Msg{
+		Extra: []dns.RR{
+			&dns.A{Hdr: dns.RR_Header{Name: "host.local.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 120}, A: mustParseIP("192.0.2.1")},
+			&dns.SRV{Hdr: dns.RR_Header{Name: "inst._http._tcp.local.", Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 120}, Priority: 0, Weight: 0, Port: 8080, Target: "host.local."},
+			&dns.TXT{Hdr: dns.RR_Header{Name: "inst._http._tcp.local.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120}, Txt: []string{"path=/", "v=1"}},
+			&dns.NSEC{Hdr: dns.RR_Header{Name: "host.local.", Rrtype: dns.TypeNSEC, Class: dns.ClassINET, Ttl: 120}},
+		},
+	}
+
+	answers := extraAnswers(msg, 5*time.Millisecond, "224.0.0.251:5353")
+	if len(answers) != 3 {
+		t.Fatalf("len(answers) = %d, want 3 (NSEC should be skipped); answers=%+v", len(answers), answers)
+	}
+
+	if answers[0].Type != "A" || answers[0].Address != "192.0.2.1" {
+		t.Errorf("answers[0] = %+v, want A 192.0.2.1", answers[0])
+	}
+	if answers[1].Type != "SRV" || answers[1].Address != "0 0 host.local.:8080" {
+		t.Errorf("answers[1] = %+v, want SRV 0 0 host.local.:8080", answers[1])
+	}
+	if answers[2].Type != "TXT" || answers[2].Address != "path=/ v=1" {
+		t.Errorf("answers[2] = %+v, want TXT path=/ v=1", answers[2])
+	}
+}
+
+func TestCorrelateServices(t *testing.T) {
+	msg := &dns.Msg{
+		Answer: []dns.RR{
+			&dns.PTR{Hdr: dns.RR_Header{Name: "_http._tcp.local.", Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: 120}, Ptr: "inst._http._tcp.local."},
+		},
+		Extra: []dns.RR{
+			&dns.SRV{Hdr: dns.RR_Header{Name: "inst._http._tcp.local.", Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 120}, Priority: 0, Weight: 0, Port: 8080, Target: "host.local."},
+			&dns.TXT{Hdr: dns.RR_Header{Name: "inst._http._tcp.local.", Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 120}, Txt: []string{"path=/"}},
+			&dns.A{Hdr: dns.RR_Header{Name: "host.local.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 120}, A: mustParseIP("192.0.2.1")},
+			&dns.AAAA{Hdr: dns.RR_Header{Name: "host.local.", Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 120}, AAAA: mustParseIP("2001:db8::1")},
+		},
+	}
+
+	services := correlateServices(msg, 5*time.Millisecond, "224.0.0.251:5353")
+	if len(services) != 1 {
+		t.Fatalf("len(services) = %d, want 1; services=%+v", len(services), services)
+	}
+
+	svc := services[0]
+	if svc.Instance != "inst._http._tcp.local." {
+		t.Errorf("Instance = %q, want %q", svc.Instance, "inst._http._tcp.local.")
+	}
+	if svc.SRV == nil || svc.SRV.Address != "0 0 host.local.:8080" {
+		t.Fatalf("SRV = %+v, want address 0 0 host.local.:8080", svc.SRV)
+	}
+	if len(svc.TXT) != 1 || svc.TXT[0].Address != "path=/" {
+		t.Fatalf("TXT = %+v, want one record with address path=/", svc.TXT)
+	}
+	if len(svc.Addrs) != 2 {
+		t.Fatalf("len(Addrs) = %d, want 2 (A and AAAA glue); Addrs=%+v", len(svc.Addrs), svc.Addrs)
+	}
+}
+
+func TestCorrelateServicesNoPTR(t *testing.T) {
+	msg := &dns.Msg{
+		Answer: []dns.RR{
+			&dns.A{Hdr: dns.RR_Header{Name: "host.local.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 120}, A: mustParseIP("192.0.2.1")},
+		},
+	}
+
+	if services := correlateServices(msg, time.Millisecond, "224.0.0.251:5353"); services != nil {
+		t.Fatalf("services = %+v, want nil when the reply has no PTR answer", services)
+	}
+}
+
+func mustParseIP(s string) net.IP {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		panic("invalid test IP: " + s)
+	}
+	return ip
+}
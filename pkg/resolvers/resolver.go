@@ -0,0 +1,57 @@
+package resolvers
+
+import "github.com/miekg/dns"
+
+// Resolver is implemented by every query backend (plain UDP/TCP, DNS-over-
+// HTTPS, DNS-over-TLS, ...) that answers a single question. AXFR and mDNS
+// don't fit this single-question/single-response shape, so they're driven
+// through their own types instead (AXFRResolver.Transfer, MDNSResolver.Lookup).
+type Resolver interface {
+	Lookup(question dns.Question) (Response, error)
+}
+
+// Response is the unified result shape every resolver normalizes its
+// replies into, regardless of the wire format or transport it queried over.
+type Response struct {
+	Answers     []Answer
+	Authorities []Authority
+	// Services holds the PTR-correlated service-discovery records built
+	// by MDNSResolver for `_service._proto.local.` queries; empty for
+	// every other resolver.
+	Services []ServiceInstance
+	// AD mirrors the reply's AD (Authenticated Data) bit: the querying
+	// nameserver's own claim that it validated the answer with DNSSEC.
+	// It's a single flag on the whole reply, not a per-record verdict —
+	// RFC 4035 defines AD at the message level. A real, independent
+	// chain-of-trust validation, record by record, is what --validate
+	// (ValidateChain) does instead; the two aren't merged into one
+	// Response because they come from fundamentally different sources of
+	// trust: AD is the nameserver's word, ValidateChain's verdict is
+	// cryptographically checked here.
+	AD bool
+}
+
+// Answer represents a single resource record returned in a DNS response's
+// Answer section.
+type Answer struct {
+	Name       string
+	Type       string
+	TTL        string
+	Class      string
+	Address    string
+	RTT        string
+	Nameserver string
+}
+
+// Authority represents a single resource record returned in a DNS
+// response's Authority section (currently only SOA records are parsed).
+type Authority struct {
+	Name       string
+	Type       string
+	TTL        string
+	Class      string
+	MName      string
+	Nameserver string
+	RTT        string
+	Status     string
+}
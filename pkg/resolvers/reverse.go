@@ -0,0 +1,66 @@
+package resolvers
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// BuildReverseQuestion rewrites an IPv4 or IPv6 literal into the appropriate
+// PTR question under in-addr.arpa or ip6.arpa, mirroring dig's `-x`
+// ergonomics so users can pass a bare address instead of the arpa name.
+func BuildReverseQuestion(addr string) (dns.Question, error) {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return dns.Question{}, fmt.Errorf("%q is not a valid IP address", addr)
+	}
+	name, err := dns.ReverseAddr(addr)
+	if err != nil {
+		return dns.Question{}, err
+	}
+	return dns.Question{
+		Name:   name,
+		Qtype:  dns.TypePTR,
+		Qclass: dns.ClassINET,
+	}, nil
+}
+
+// BuildClasslessReverseQuestion builds a PTR question for a classless (RFC
+// 2317) IPv4 reverse delegation, where the zone boundary falls on a
+// non-octet bit (/25 through /31). The delegated zone name takes the form
+// "<first-octet>/<mask>.<rest of in-addr.arpa>", e.g. for 192.0.2.1/27 the
+// query name is "1/27.2.0.192.in-addr.arpa.".
+func BuildClasslessReverseQuestion(addr string, mask int) (dns.Question, error) {
+	if mask < 25 || mask > 31 {
+		return dns.Question{}, fmt.Errorf("classless reverse delegation mask must be between /25 and /31, got /%d", mask)
+	}
+	ip := net.ParseIP(addr).To4()
+	if ip == nil {
+		return dns.Question{}, fmt.Errorf("%q is not a valid IPv4 address", addr)
+	}
+
+	arpa, err := dns.ReverseAddr(addr)
+	if err != nil {
+		return dns.Question{}, err
+	}
+	labels := strings.SplitN(arpa, ".", 2)
+	if len(labels) != 2 {
+		return dns.Question{}, fmt.Errorf("could not construct classless reverse name for %q", addr)
+	}
+	name := labels[0] + "/" + strconv.Itoa(mask) + "." + labels[1]
+
+	return dns.Question{
+		Name:   name,
+		Qtype:  dns.TypePTR,
+		Qclass: dns.ClassINET,
+	}, nil
+}
+
+// IsReverseQuery reports whether q is an IPv4 or IPv6 literal that should be
+// rewritten into a PTR query, rather than a regular hostname.
+func IsReverseQuery(name string) bool {
+	return net.ParseIP(name) != nil
+}
@@ -0,0 +1,68 @@
+package resolvers
+
+import "testing"
+
+func TestBuildReverseQuestion(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{"ipv4", "192.0.2.1", "1.2.0.192.in-addr.arpa.", false},
+		{"ipv6", "2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", false},
+		{"invalid", "not-an-ip", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildReverseQuestion(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildReverseQuestion(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+			}
+			if err == nil && got.Name != tt.want {
+				t.Errorf("BuildReverseQuestion(%q).Name = %q, want %q", tt.addr, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildClasslessReverseQuestion(t *testing.T) {
+	tests := []struct {
+		name    string
+		addr    string
+		mask    int
+		want    string
+		wantErr bool
+	}{
+		{"slash 27", "192.0.2.1", 27, "1/27.2.0.192.in-addr.arpa.", false},
+		{"slash 25", "10.1.1.200", 25, "200/25.1.1.10.in-addr.arpa.", false},
+		{"mask too small", "192.0.2.1", 24, "", true},
+		{"mask too large", "192.0.2.1", 32, "", true},
+		{"not ipv4", "2001:db8::1", 27, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildClasslessReverseQuestion(tt.addr, tt.mask)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildClasslessReverseQuestion(%q, %d) error = %v, wantErr %v", tt.addr, tt.mask, err, tt.wantErr)
+			}
+			if err == nil && got.Name != tt.want {
+				t.Errorf("BuildClasslessReverseQuestion(%q, %d).Name = %q, want %q", tt.addr, tt.mask, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsReverseQuery(t *testing.T) {
+	if !IsReverseQuery("192.0.2.1") {
+		t.Error("expected 192.0.2.1 to be a reverse query")
+	}
+	if !IsReverseQuery("2001:db8::1") {
+		t.Error("expected 2001:db8::1 to be a reverse query")
+	}
+	if IsReverseQuery("example.com") {
+		t.Error("expected example.com not to be a reverse query")
+	}
+}
@@ -0,0 +1,127 @@
+package resolvers
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// SOACheckResult holds the SOA record reported by a single authoritative
+// nameserver for a zone, along with how long the query took to complete.
+// It is modelled on the output of the classic DNS&BIND `check_soa` utility.
+type SOACheckResult struct {
+	Nameserver string `json:"nameserver"`
+	Serial     uint32 `json:"serial"`
+	Refresh    uint32 `json:"refresh"`
+	Retry      uint32 `json:"retry"`
+	Expire     uint32 `json:"expire"`
+	MinTTL     uint32 `json:"minttl"`
+	RTT        string `json:"rtt"`
+	Error      string `json:"error,omitempty"`
+}
+
+// SOACheckResponse is the aggregate result of probing every authoritative
+// nameserver for a zone's SOA record.
+type SOACheckResponse struct {
+	Zone    string           `json:"zone"`
+	Results []SOACheckResult `json:"results"`
+	InSync  bool             `json:"in_sync"`
+}
+
+// CheckSOA discovers the authoritative nameservers for zone by resolving its
+// NS RRset through parent, then queries each nameserver directly (non-
+// recursively) for the zone's SOA record. It flags whether all of the
+// returned serials agree, which is the main signal operators use this mode
+// for: catching nameservers that have fallen out of sync after a zone update.
+func CheckSOA(zone string, parent Resolver) (SOACheckResponse, error) {
+	fqdn := dns.Fqdn(zone)
+	resp := SOACheckResponse{Zone: fqdn}
+
+	nsResp, err := parent.Lookup(dns.Question{Name: fqdn, Qtype: dns.TypeNS, Qclass: dns.ClassINET})
+	if err != nil {
+		return resp, fmt.Errorf("error resolving NS records for %s: %v", fqdn, err)
+	}
+
+	client := new(dns.Client)
+	var serials []uint32
+
+	for _, ans := range nsResp.Answers {
+		if ans.Type != "NS" {
+			continue
+		}
+		nsHost := dns.Fqdn(ans.Address)
+
+		glueAddr, err := resolveGlue(parent, nsHost)
+		if err != nil {
+			resp.Results = append(resp.Results, SOACheckResult{
+				Nameserver: nsHost,
+				Error:      "could not resolve a glue address",
+			})
+			continue
+		}
+
+		addr := net.JoinHostPort(glueAddr, "53")
+		msg := dns.Msg{}
+		msg.Id = dns.Id()
+		msg.RecursionDesired = false
+		msg.Question = []dns.Question{{Name: fqdn, Qtype: dns.TypeSOA, Qclass: dns.ClassINET}}
+
+		reply, rtt, err := client.Exchange(&msg, addr)
+		if err != nil {
+			resp.Results = append(resp.Results, SOACheckResult{Nameserver: nsHost, Error: err.Error()})
+			continue
+		}
+
+		result := SOACheckResult{
+			Nameserver: nsHost,
+			RTT:        fmt.Sprintf("%dms", rtt.Milliseconds()),
+		}
+		for _, rr := range reply.Answer {
+			soa, ok := rr.(*dns.SOA)
+			if !ok {
+				continue
+			}
+			result.Serial = soa.Serial
+			result.Refresh = soa.Refresh
+			result.Retry = soa.Retry
+			result.Expire = soa.Expire
+			result.MinTTL = soa.Minttl
+			serials = append(serials, soa.Serial)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	resp.InSync = allSerialsMatch(serials)
+
+	return resp, nil
+}
+
+// resolveGlue resolves an NS hostname to a dialable address, reusing the
+// parent resolver. It tries A first and falls back to AAAA, since an
+// IPv6-only nameserver is perfectly reachable even without an A record.
+func resolveGlue(parent Resolver, nsHost string) (string, error) {
+	a, err := parent.Lookup(dns.Question{Name: nsHost, Qtype: dns.TypeA, Qclass: dns.ClassINET})
+	if err == nil && len(a.Answers) > 0 {
+		return a.Answers[0].Address, nil
+	}
+
+	aaaa, err := parent.Lookup(dns.Question{Name: nsHost, Qtype: dns.TypeAAAA, Qclass: dns.ClassINET})
+	if err == nil && len(aaaa.Answers) > 0 {
+		return aaaa.Answers[0].Address, nil
+	}
+
+	return "", fmt.Errorf("no A/AAAA glue found for %s", nsHost)
+}
+
+// allSerialsMatch reports whether every SOA serial observed across the
+// probed nameservers agrees. An empty or single-element slice trivially
+// matches.
+func allSerialsMatch(serials []uint32) bool {
+	for _, s := range serials {
+		if s != serials[0] {
+			return false
+		}
+	}
+	return true
+}
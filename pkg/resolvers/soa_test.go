@@ -0,0 +1,24 @@
+package resolvers
+
+import "testing"
+
+func TestAllSerialsMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		serials []uint32
+		want    bool
+	}{
+		{"empty", nil, true},
+		{"single", []uint32{2026072901}, true},
+		{"all match", []uint32{2026072901, 2026072901, 2026072901}, true},
+		{"one stale", []uint32{2026072901, 2026072901, 2026072800}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allSerialsMatch(tt.serials); got != tt.want {
+				t.Errorf("allSerialsMatch(%v) = %v, want %v", tt.serials, got, tt.want)
+			}
+		})
+	}
+}
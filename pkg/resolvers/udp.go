@@ -0,0 +1,35 @@
+package resolvers
+
+import (
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// UDPResolver performs a single-shot plain UDP query against one
+// nameserver, with no retries and no fallback to TCP on truncation.
+type UDPResolver struct {
+	server string
+}
+
+// NewUDPResolver instantiates a UDPResolver for the given nameserver
+// address (host:port).
+func NewUDPResolver(server string) (*UDPResolver, error) {
+	return &UDPResolver{server: server}, nil
+}
+
+// Lookup sends question to the resolver's server and returns the parsed
+// Response.
+func (r *UDPResolver) Lookup(question dns.Question) (Response, error) {
+	msg := dns.Msg{}
+	msg.Id = dns.Id()
+	msg.RecursionDesired = true
+	msg.Question = []dns.Question{question}
+
+	client := new(dns.Client)
+	reply, rtt, err := client.Exchange(&msg, r.server)
+	if err != nil {
+		return Response{}, fmt.Errorf("error querying %s: %v", r.server, err)
+	}
+	return parseMessage(reply, rtt, r.server), nil
+}
@@ -3,14 +3,24 @@ package resolvers
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/miekg/dns"
 )
 
 // prepareMessages takes a  DNS Question and returns the
-// corresponding DNS messages for the same.
-func prepareMessages(q dns.Question, ndots int, searchList []string) []dns.Msg {
+// corresponding DNS messages for the same. The question name is first
+// normalized to ASCII through IDNA2008 (see toASCII); a name containing
+// invalid Unicode is rejected here rather than silently sent on the wire as
+// a malformed query.
+func prepareMessages(q dns.Question, ndots int, searchList []string) ([]dns.Msg, error) {
+	ascii, err := toASCII(q.Name)
+	if err != nil {
+		return nil, err
+	}
+	q.Name = ascii
+
 	var (
 		possibleQNames = constructPossibleQuestions(q.Name, ndots, searchList)
 		messages       = make([]dns.Msg, 0, len(possibleQNames))
@@ -30,9 +40,96 @@ func prepareMessages(q dns.Question, ndots int, searchList []string) []dns.Msg {
 		messages = append(messages, msg)
 	}
 
+	return messages, nil
+}
+
+// prepareDNSSECMessages behaves like prepareMessages but additionally sets
+// the EDNS0 OPT record with the DO (DNSSEC OK) bit, requesting signatures
+// (RRSIG/DNSKEY/NSEC/NSEC3) from signed zones. It's used when the user
+// passes `--dnssec`.
+func prepareDNSSECMessages(q dns.Question, ndots int, searchList []string) ([]dns.Msg, error) {
+	messages, err := prepareMessages(q, ndots, searchList)
+	if err != nil {
+		return nil, err
+	}
+	for i := range messages {
+		messages[i].SetEdns0(4096, true)
+	}
+	return messages, nil
+}
+
+// chaosQueries are the standard CHAOS-class diagnostic queries supported by
+// most nameserver implementations (BIND, Knot, PowerDNS, ...) for identifying
+// the running software and host.
+var chaosQueries = []string{"version.bind.", "hostname.bind.", "id.server."}
+
+// prepareChaosMessages builds the CHAOS-class TXT queries (version.bind,
+// hostname.bind, id.server) used to fingerprint a nameserver. Unlike
+// prepareMessages, these names are always absolute and sent as-is, with no
+// ndots/search-list expansion.
+func prepareChaosMessages() []dns.Msg {
+	messages := make([]dns.Msg, 0, len(chaosQueries))
+	for _, name := range chaosQueries {
+		msg := dns.Msg{}
+		// generate a random id for the transaction.
+		msg.Id = dns.Id()
+		msg.RecursionDesired = true
+		msg.Question = []dns.Question{{
+			Name:   name,
+			Qtype:  dns.TypeTXT,
+			Qclass: dns.ClassCHAOS,
+		}}
+		messages = append(messages, msg)
+	}
 	return messages
 }
 
+// ChaosLookup sends the CHAOS-class diagnostic queries built by
+// prepareChaosMessages to server over UDP and collates the replies into a
+// single Response. It's the entry point invoked when the user passes
+// `--chaos`.
+func ChaosLookup(server string) (Response, error) {
+	var resp Response
+	client := new(dns.Client)
+
+	for _, msg := range prepareChaosMessages() {
+		reply, rtt, err := client.Exchange(&msg, server)
+		if err != nil {
+			return resp, fmt.Errorf("error querying %s: %v", server, err)
+		}
+		parsed := parseMessage(reply, rtt, server)
+		resp.Answers = append(resp.Answers, parsed.Answers...)
+	}
+
+	return resp, nil
+}
+
+// DNSSECLookup behaves like a regular lookup but requests DNSSEC
+// signatures via prepareDNSSECMessages, sending q (ndots/search-list
+// expanded) to server and collating the replies into a single Response.
+// It's the entry point invoked when the user passes `--dnssec`.
+func DNSSECLookup(q dns.Question, server string, ndots int, searchList []string) (Response, error) {
+	var resp Response
+	client := new(dns.Client)
+
+	messages, err := prepareDNSSECMessages(q, ndots, searchList)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, msg := range messages {
+		reply, rtt, err := client.Exchange(&msg, server)
+		if err != nil {
+			return resp, fmt.Errorf("error querying %s: %v", server, err)
+		}
+		parsed := parseMessage(reply, rtt, server)
+		resp.Answers = append(resp.Answers, parsed.Answers...)
+		resp.Authorities = append(resp.Authorities, parsed.Authorities...)
+	}
+
+	return resp, nil
+}
+
 // NameList returns all of the names that should be queried based on the
 // config. It is based off of go's net/dns name building, but it does not
 // check the length of the resulting names.
@@ -67,10 +164,35 @@ func constructPossibleQuestions(name string, ndots int, searchList []string) []s
 	return names
 }
 
+// ToUnicodeDisplay rewrites every Name field in resp from its on-the-wire
+// A-label back to the Unicode U-label a user typed, e.g. "xn--caf-dma."
+// becomes "café.". Used when the `--unicode` flag is passed.
+func ToUnicodeDisplay(resp Response) Response {
+	for i := range resp.Answers {
+		resp.Answers[i].Name = toUnicode(resp.Answers[i].Name)
+	}
+	for i := range resp.Authorities {
+		resp.Authorities[i].Name = toUnicode(resp.Authorities[i].Name)
+	}
+	return resp
+}
+
+// typeBitMapString renders the RR type bitmap carried by NSEC/NSEC3 records
+// (the set of types that exist at the covered name) as a space separated
+// list of type mnemonics, e.g. "A AAAA RRSIG NSEC".
+func typeBitMapString(types []uint16) string {
+	names := make([]string, 0, len(types))
+	for _, t := range types {
+		names = append(names, dns.Type(t).String())
+	}
+	return strings.Join(names, " ")
+}
+
 // parseMessage takes a `dns.Message` and returns a custom
 // Response data struct.
 func parseMessage(msg *dns.Msg, rtt time.Duration, server string) Response {
 	var resp Response
+	resp.AD = msg.AuthenticatedData
 	timeTaken := fmt.Sprintf("%dms", rtt.Milliseconds())
 
 	// Parse Authorities section.
@@ -107,35 +229,7 @@ func parseMessage(msg *dns.Msg, rtt time.Duration, server string) Response {
 	}
 	// Parse Answers section.
 	for _, a := range msg.Answer {
-		addr := ""
-		switch t := a.(type) {
-		case *dns.A:
-			addr = t.A.String()
-		case *dns.AAAA:
-			addr = t.AAAA.String()
-		case *dns.CNAME:
-			addr = t.Target
-		case *dns.CAA:
-			addr = t.Tag + " " + t.Value
-		case *dns.HINFO:
-			addr = t.Cpu + " " + t.Os
-		case *dns.PTR:
-			addr = t.Ptr
-		case *dns.SRV:
-			addr = strconv.Itoa(int(t.Priority)) + " " +
-				strconv.Itoa(int(t.Weight)) + " " +
-				t.Target + ":" + strconv.Itoa(int(t.Port))
-		case *dns.TXT:
-			addr = t.String()
-		case *dns.NS:
-			addr = t.Ns
-		case *dns.MX:
-			addr = strconv.Itoa(int(t.Preference)) + " " + t.Mx
-		case *dns.SOA:
-			addr = t.String()
-		case *dns.NAPTR:
-			addr = t.String()
-		}
+		addr := rdataString(a)
 		h := a.Header()
 		name := h.Name
 		qclass := dns.Class(h.Class).String()
@@ -154,3 +248,53 @@ func parseMessage(msg *dns.Msg, rtt time.Duration, server string) Response {
 	}
 	return resp
 }
+
+// rdataString renders just the value of rr's rdata, the way Answer.Address
+// expects it: Name/Type/TTL/Class already have their own fields, so this
+// deliberately doesn't include them the way (dns.RR).String() would.
+func rdataString(rr dns.RR) string {
+	switch t := rr.(type) {
+	case *dns.A:
+		return t.A.String()
+	case *dns.AAAA:
+		return t.AAAA.String()
+	case *dns.CNAME:
+		return t.Target
+	case *dns.CAA:
+		return t.Tag + " " + t.Value
+	case *dns.HINFO:
+		return t.Cpu + " " + t.Os
+	case *dns.PTR:
+		return t.Ptr
+	case *dns.SRV:
+		return strconv.Itoa(int(t.Priority)) + " " +
+			strconv.Itoa(int(t.Weight)) + " " +
+			t.Target + ":" + strconv.Itoa(int(t.Port))
+	case *dns.TXT:
+		return t.String()
+	case *dns.NS:
+		return t.Ns
+	case *dns.MX:
+		return strconv.Itoa(int(t.Preference)) + " " + t.Mx
+	case *dns.SOA:
+		return t.String()
+	case *dns.NAPTR:
+		return t.String()
+	case *dns.RRSIG:
+		return strconv.Itoa(int(t.Algorithm)) + " " +
+			strconv.Itoa(int(t.KeyTag)) + " " + t.SignerName + " " +
+			dns.TimeToString(t.Inception) + " " + dns.TimeToString(t.Expiration)
+	case *dns.DNSKEY:
+		return strconv.Itoa(int(t.Flags)) + " " +
+			strconv.Itoa(int(t.Algorithm)) + " " + strconv.Itoa(int(t.KeyTag()))
+	case *dns.DS:
+		return strconv.Itoa(int(t.KeyTag)) + " " +
+			strconv.Itoa(int(t.Algorithm)) + " " +
+			strconv.Itoa(int(t.DigestType)) + " " + t.Digest
+	case *dns.NSEC:
+		return t.NextDomain + " " + typeBitMapString(t.TypeBitMap)
+	case *dns.NSEC3:
+		return t.NextDomain + " " + typeBitMapString(t.TypeBitMap)
+	}
+	return ""
+}